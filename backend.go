@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Backend is anything e-z can upload files to and shorten URLs through.
+// ezHostBackend is the default; sxcuBackend lets users point at any
+// ShareX-compatible custom-uploader host instead.
+type Backend interface {
+	Upload(r io.Reader, name, mimeType string) (*Response, error)
+	Shorten(url string) (*Response, error)
+}
+
+// ezHostBackend talks to the e-z.host API directly.
+type ezHostBackend struct {
+	apiKey string
+}
+
+func newEzHostBackend(apiKey string) *ezHostBackend {
+	return &ezHostBackend{apiKey: apiKey}
+}
+
+func (b *ezHostBackend) Upload(r io.Reader, name, mimeType string) (*Response, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		part, err := createFormFilePart(writer, "file", name, mimeType)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, r); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(writer.Close())
+	}()
+
+	req, err := http.NewRequest("POST", "https://api.e-z.host/files", pr)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("key", b.apiKey)
+
+	return doUploadRequest(req)
+}
+
+func (b *ezHostBackend) Shorten(url string) (*Response, error) {
+	return shortenURLData(b.apiKey, url)
+}
+
+// loadBackend resolves the Backend to upload through: the built-in
+// e-z.host API, or a ShareX custom-uploader config when backendPath is
+// set via --backend.
+func loadBackend(backendPath, apiKey string) (Backend, error) {
+	if backendPath == "" {
+		return newEzHostBackend(apiKey), nil
+	}
+
+	cfg, err := loadSXCUConfig(backendPath)
+	if err != nil {
+		return nil, err
+	}
+	return newSXCUBackend(cfg), nil
+}
+
+// uploadViaBackend runs filePath through backend's upload pipeline. Unlike
+// uploadFile, it has no progress bar or resumable chunking support since
+// those are e-z.host-specific extensions that an arbitrary ShareX-style
+// host can't be assumed to support.
+func uploadViaBackend(filePath string, backend Backend, copyRawURL bool, allowedMimePatterns []string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("stat %q: %w", filePath, err)
+	}
+	if info.Size() > maxFileSize {
+		return fmt.Errorf("file size exceeds 100MB")
+	}
+
+	mimeType, err := detectMimeType(filePath)
+	if err != nil {
+		return fmt.Errorf("detecting MIME type: %w", err)
+	}
+	if !mimeAllowed(mimeType, allowedMimePatterns) {
+		return fmt.Errorf("MIME type %q is not allowed", mimeType)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	resp, err := backend.Upload(file, filepath.Base(filePath), mimeType)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("upload failed: %s", resp.Message)
+	}
+
+	urlToCopy := resp.RawURL
+	if !copyRawURL || urlToCopy == "" {
+		urlToCopy = resp.ImageURL
+	}
+	copyToClipboard(urlToCopy)
+	fmt.Println("File uploaded and URL copied to clipboard.")
+
+	if sum, err := sha256File(filePath); err == nil {
+		entry := historyEntry{
+			Timestamp:   time.Now(),
+			LocalPath:   filePath,
+			SHA256:      sum,
+			ImageURL:    resp.ImageURL,
+			RawURL:      resp.RawURL,
+			DeletionURL: resp.DeletionURL,
+		}
+		if err := recordHistory(entry); err != nil {
+			log.Printf("Warning: failed to record upload history: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// uploadResult is one entry of the JSON manifest produced by a batch
+// upload, recording the outcome of a single file.
+type uploadResult struct {
+	Path        string `json:"path"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+	RawURL      string `json:"rawUrl,omitempty"`
+	DeletionURL string `json:"deletionUrl,omitempty"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// resolveUploadPaths expands globs in args and, for any directory among
+// the matches, walks its contents when recursive is true. Plain files are
+// passed through unchanged.
+func resolveUploadPaths(args []string, recursive bool) ([]string, error) {
+	var files []string
+
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			matches = []string{arg}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("stat %q: %w", match, err)
+			}
+
+			if !info.IsDir() {
+				files = append(files, match)
+				continue
+			}
+
+			if !recursive {
+				return nil, fmt.Errorf("%q is a directory; pass --recursive to upload its contents", match)
+			}
+
+			err = filepath.Walk(match, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if !info.IsDir() {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("walking %q: %w", match, err)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+type uploadJob struct {
+	index int
+	path  string
+}
+
+// runBatchUpload uploads paths through a bounded pool of concurrency
+// workers, each running the regular uploadFileData pipeline. A failure on
+// one file doesn't stop the others; it's simply recorded in that file's
+// result.
+func runBatchUpload(paths []string, apiKey string, concurrency int, resume bool, allowedMimePatterns []string) []uploadResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan uploadJob)
+	results := make([]uploadResult, len(paths))
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results[job.index] = uploadOne(job.path, apiKey, resume, allowedMimePatterns)
+			}
+		}()
+	}
+
+	go func() {
+		for i, path := range paths {
+			jobs <- uploadJob{index: i, path: path}
+		}
+		close(jobs)
+	}()
+
+	wg.Wait()
+	return results
+}
+
+func uploadOne(path, apiKey string, resume bool, allowedMimePatterns []string) uploadResult {
+	result := uploadResult{Path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Size = info.Size()
+
+	sum, err := sha256File(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.SHA256 = sum
+
+	resp, err := uploadFileData(path, apiKey, resume, allowedMimePatterns, true)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if !resp.Success {
+		result.Error = resp.Message
+		return result
+	}
+
+	result.ImageURL = resp.ImageURL
+	result.RawURL = resp.RawURL
+	result.DeletionURL = resp.DeletionURL
+
+	entry := historyEntry{
+		Timestamp:   time.Now(),
+		LocalPath:   path,
+		SHA256:      result.SHA256,
+		ImageURL:    resp.ImageURL,
+		RawURL:      resp.RawURL,
+		DeletionURL: resp.DeletionURL,
+	}
+	if err := recordHistory(entry); err != nil {
+		log.Printf("Warning: failed to record upload history for %s: %v", path, err)
+	}
+
+	return result
+}
+
+func sha256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeManifest prints the batch results as JSON to stdout, or writes them
+// to manifestPath if one was given via --manifest.
+func writeManifest(results []uploadResult, manifestPath string) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling manifest: %w", err)
+	}
+
+	if manifestPath == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return os.WriteFile(manifestPath, data, 0644)
+}
+
+// summarizeBatch reports a succeeded/failed count to stderr and returns
+// whether any upload in the batch failed. It goes to stderr, like the
+// progress bar, so a redirected manifest (e-z --upload *.png > out.json)
+// stays clean JSON.
+func summarizeBatch(results []uploadResult) (failed int) {
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Batch upload complete: %d succeeded, %d failed\n", len(results)-failed, failed)
+	return failed
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// copyToClipboard copies text to the system clipboard using the native
+// tool for the current platform, piping it through the command's stdin
+// rather than interpolating it into a shell string.
+func copyToClipboard(text string) {
+	cmd, err := clipboardCommand()
+	if err != nil {
+		log.Printf("Error copying to clipboard: %v", err)
+		fmt.Println("Please copy manually.")
+		return
+	}
+
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		log.Printf("Error copying to clipboard: %v", err)
+		fmt.Println("Please copy manually.")
+	}
+}
+
+// clipboardCommand resolves the clipboard tool for the current platform:
+// pbcopy on macOS, clip.exe on Windows, and the first of wl-copy, xclip,
+// or xsel found on PATH on Linux.
+func clipboardCommand() (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("pbcopy"), nil
+	case "windows":
+		return exec.Command("clip.exe"), nil
+	case "linux":
+		for _, name := range []string{"wl-copy", "xclip", "xsel"} {
+			path, err := exec.LookPath(name)
+			if err != nil {
+				continue
+			}
+			switch name {
+			case "xclip":
+				return exec.Command(path, "-selection", "clipboard"), nil
+			case "xsel":
+				return exec.Command(path, "--clipboard", "--input"), nil
+			default:
+				return exec.Command(path), nil
+			}
+		}
+		return nil, fmt.Errorf("no clipboard tool found (tried wl-copy, xclip, xsel)")
+	default:
+		return nil, fmt.Errorf("clipboard not supported on %s", runtime.GOOS)
+	}
+}
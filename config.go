@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Config holds user-configurable settings loaded from the config.yaml in
+// appConfigDir. Only the fields e-z currently understands are parsed;
+// unrecognized keys are ignored.
+type Config struct {
+	AllowedMimePatterns []string
+}
+
+func configFilePath() string {
+	return filepath.Join(appConfigDir(), "config.yaml")
+}
+
+// loadConfig reads the allow_mime list from the user's config.yaml, falling
+// back to defaultAllowedMimePatterns if the file is absent or the key isn't
+// set. The parser only understands the minimal subset of YAML this file
+// needs: a top-level "allow_mime:" key followed by "- pattern" list items.
+func loadConfig() Config {
+	cfg := Config{AllowedMimePatterns: defaultAllowedMimePatterns}
+
+	file, err := os.Open(configFilePath())
+	if err != nil {
+		return cfg
+	}
+	defer file.Close()
+
+	var patterns []string
+	inAllowMime := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "allow_mime:":
+			inAllowMime = true
+		case inAllowMime && strings.HasPrefix(trimmed, "- "):
+			patterns = append(patterns, strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+		case trimmed == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+			// blank or indented continuation line; stay on the current key
+		default:
+			inAllowMime = false
+		}
+	}
+
+	if len(patterns) > 0 {
+		cfg.AllowedMimePatterns = patterns
+	}
+	return cfg
+}
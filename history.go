@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyEntry records one successful upload so its deletion URL can be
+// looked up later without having to keep it around by hand.
+type historyEntry struct {
+	Timestamp    time.Time `json:"timestamp"`
+	LocalPath    string    `json:"localPath"`
+	SHA256       string    `json:"sha256"`
+	ImageURL     string    `json:"imageUrl"`
+	RawURL       string    `json:"rawUrl"`
+	ShortenedURL string    `json:"shortenedUrl,omitempty"`
+	DeletionURL  string    `json:"deletionUrl"`
+}
+
+func historyFilePath() string {
+	return filepath.Join(appConfigDir(), "history.db")
+}
+
+// recordHistory appends entry as one line of JSON to the history file.
+func recordHistory(entry historyEntry) error {
+	if err := os.MkdirAll(filepath.Dir(historyFilePath()), os.ModePerm); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	file, err := os.OpenFile(historyFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening history file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshaling history entry: %w", err)
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// loadHistory reads every recorded upload, oldest first.
+func loadHistory() ([]historyEntry, error) {
+	file, err := os.Open(historyFilePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry historyEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("parsing history entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// printHistory prints the most recent uploads first, as a table or as
+// JSON lines.
+func printHistory(entries []historyEntry, asJSON bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if asJSON {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				continue
+			}
+			fmt.Println(string(data))
+			continue
+		}
+
+		index := len(entries) - i
+		fmt.Printf("[%d] %s  %s  %s\n", index, entry.Timestamp.Format(time.RFC3339), entry.LocalPath, entry.ImageURL)
+	}
+}
+
+// findDeletionURL resolves urlOrIndex, which is either a 1-based index
+// into the most-recent-first history listing (as printed by
+// printHistory) or one of the recorded URLs, to its deletion URL.
+func findDeletionURL(entries []historyEntry, urlOrIndex string) (string, error) {
+	if index, err := strconv.Atoi(urlOrIndex); err == nil {
+		pos := len(entries) - index
+		if pos < 0 || pos >= len(entries) {
+			return "", fmt.Errorf("no history entry at index %d", index)
+		}
+		return entries[pos].DeletionURL, nil
+	}
+
+	for _, entry := range entries {
+		if entry.ImageURL == urlOrIndex || entry.RawURL == urlOrIndex || entry.ShortenedURL == urlOrIndex {
+			return entry.DeletionURL, nil
+		}
+	}
+	return "", fmt.Errorf("no history entry matches %q", urlOrIndex)
+}
+
+// deleteUpload issues the DELETE request for a deletion URL returned by
+// the API at upload time.
+func deleteUpload(deletionURL string) error {
+	req, err := http.NewRequest(http.MethodDelete, deletionURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("performing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("received non-OK response: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// parseRetention parses a duration like "30d", accepting a trailing "d"
+// for days in addition to everything time.ParseDuration understands.
+func parseRetention(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// purgeOlderThan deletes every recorded upload older than cutoff,
+// rewriting the history file to drop the ones it successfully deletes.
+func purgeOlderThan(olderThan time.Duration) error {
+	entries, err := loadHistory()
+	if err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var kept []historyEntry
+	purged, failed := 0, 0
+
+	for _, entry := range entries {
+		if entry.Timestamp.After(cutoff) {
+			kept = append(kept, entry)
+			continue
+		}
+
+		if err := deleteUpload(entry.DeletionURL); err != nil {
+			fmt.Printf("Failed to delete %s: %v\n", entry.ImageURL, err)
+			kept = append(kept, entry)
+			failed++
+			continue
+		}
+		purged++
+	}
+
+	if err := rewriteHistory(kept); err != nil {
+		return fmt.Errorf("rewriting history: %w", err)
+	}
+
+	fmt.Printf("Purged %d upload(s), %d failed\n", purged, failed)
+	return nil
+}
+
+func rewriteHistory(entries []historyEntry) error {
+	var buf strings.Builder
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(historyFilePath(), []byte(buf.String()), 0644)
+}
@@ -4,14 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
-  "io/ioutil"
+	"io/ioutil"
 	"log"
-	"mime/multipart"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -24,6 +22,7 @@ type Response struct {
 	RawURL       string `json:"rawUrl"`
 	ShortenedURL string `json:"shortendUrl"` // Corrected to match the API response
 	DeletionURL  string `json:"deletionUrl"`
+	SessionID    string `json:"sessionId,omitempty"` // set on chunked/resumable uploads
 }
 
 func displayHelp() {
@@ -32,16 +31,24 @@ func displayHelp() {
 	fmt.Println("\nOptions:")
 	fmt.Println("  --help, -h                     Display this help message")
 	fmt.Println("  --api-key, -a [API_KEY]        Store an API key (prompt if API_KEY is not provided)")
-	fmt.Println("  --upload, -u [FILE_PATH]       Upload a file to the API (prompt if FILE_PATH is not provided)")
-	fmt.Println("  --upload-raw, -ur [FILE_PATH]  Same as the above option, but copies the raw URL to the clipboard")
+	fmt.Println("  --upload, -u [PATH...]         Upload one or more files, globs, or (with --recursive) directories")
+	fmt.Println("  --upload-raw, -ur [PATH...]    Same as the above option, but copies the raw URL to the clipboard")
+	fmt.Println("  --recursive                    Descend into directories passed to --upload")
+	fmt.Println("  --concurrency N                Number of files to upload in parallel for a batch (default 4)")
+	fmt.Println("  --manifest [FILE]              Write the batch upload manifest to FILE instead of stdout")
+	fmt.Println("  --resume                       Upload in resumable chunks, tracking progress in a .e-z_state sidecar")
+	fmt.Println("  --allow-mime [PATTERNS]        Comma-separated MIME patterns to allow for this invocation, e.g. image/*,video/mp4")
 	fmt.Println("  --shorten, -s [URL]            Shorten a given URL using the API")
+	fmt.Println("  --history [--json]             List recorded uploads, most recent first")
+	fmt.Println("  --delete [URL_OR_INDEX]        Delete an upload by its URL or --history index")
+	fmt.Println("  --purge-older-than [DURATION]  Delete every recorded upload older than DURATION, e.g. 30d")
+	fmt.Println("  --backend [SXCU_FILE]          Upload/shorten through a ShareX-compatible custom-uploader config instead of e-z.host")
 }
 
 func saveApiKey(apiKey string) {
-	configDir := filepath.Join(os.Getenv("HOME"), ".config")
-	filePath := filepath.Join(configDir, ".e-z_key")
+	filePath := apiKeyPath()
 
-	if err := os.MkdirAll(configDir, os.ModePerm); err != nil {
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
 		log.Fatalf("Error creating config directory: %v", err)
 	}
 
@@ -52,8 +59,7 @@ func saveApiKey(apiKey string) {
 }
 
 func readApiKey() string {
-	filePath := filepath.Join(os.Getenv("HOME"), ".config", ".e-z_key")
-	data, err := os.ReadFile(filePath)
+	data, err := os.ReadFile(apiKeyPath())
 	if err != nil {
 		log.Println("No API key found. File does not exist.")
 		return ""
@@ -61,129 +67,34 @@ func readApiKey() string {
 	return string(data)
 }
 
-func isValidMimeType(filePath string) bool {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	validMimeTypes := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".gif":  true,
-		".mp3":  true,
-		".wav":  true,
-		".mp4":  true,
-		".avi":  true,
-		".pdf":  true,
-		".zip":  true,
-		".json": true,
-	}
-	return validMimeTypes[ext]
-}
-
-func copyToClipboard(text string) {
-	cmd := exec.Command("bash", "-c", fmt.Sprintf("echo -n \"%s\" | xclip -selection clipboard", text))
-	if err := cmd.Run(); err != nil {
-		log.Printf("Error copying to clipboard: %v", err)
-		fmt.Println("Please copy manually.")
-	}
-}
-
-func uploadFile(filePath, apiKey string, copyRawURL bool) {
-	fileInfo, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
-		log.Println("Error: File does not exist.")
-		return
-	}
-
-	if fileInfo.Size() > maxFileSize {
-		log.Println("Error: File size exceeds 100MB.")
-		return
-	}
-
-	if !isValidMimeType(filePath) {
-		log.Println("Error: Invalid MIME type for the file.")
-		return
-	}
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		log.Printf("Error opening file: %v", err)
-		return
-	}
-	defer file.Close()
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
-	if err != nil {
-		log.Printf("Error creating form file: %v", err)
-		return
-	}
-
-	if _, err := io.Copy(part, file); err != nil {
-		log.Printf("Error copying file data: %v", err)
-		return
-	}
-
-	if err := writer.Close(); err != nil {
-		log.Printf("Error closing writer: %v", err)
-		return
-	}
-
-	req, err := http.NewRequest("POST", "https://api.e-z.host/files", body)
-	if err != nil {
-		log.Printf("Error creating request: %v", err)
-		return
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("key", apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// shortenURL shortens url via the e-z.host API and copies the result to
+// the clipboard. Backends other than e-z.host go through Backend.Shorten
+// instead; see backend.go.
+func shortenURL(apiKey, url string) {
+	jsonResponse, err := shortenURLData(apiKey, url)
 	if err != nil {
-		log.Printf("Error performing request: %v", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		responseBody, _ := ioutil.ReadAll(resp.Body)
-		log.Printf("Error: Received non-OK response: %d\nResponse: %s", resp.StatusCode, string(responseBody))
-		return
-	}
-
-	var jsonResponse Response
-	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
-		log.Println("Failed to parse JSON response.")
+		log.Printf("Error shortening URL: %v", err)
 		return
 	}
 
 	if jsonResponse.Success {
-		urlToCopy := jsonResponse.RawURL
-		if !copyRawURL {
-			urlToCopy = jsonResponse.ImageURL
-		}
-
-		copyToClipboard(urlToCopy)
-		fmt.Println("File uploaded and URL copied to clipboard.")
+		fmt.Println("Shortened URL:", jsonResponse.ShortenedURL)
+		copyToClipboard(jsonResponse.ShortenedURL)
 	} else {
-		log.Println("Upload failed:", jsonResponse.Message)
+		log.Println("URL shortening failed:", jsonResponse.Message)
 	}
 }
 
-func shortenURL(apiKey, url string) {
+func shortenURLData(apiKey, url string) (*Response, error) {
 	data := map[string]string{"url": url}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		log.Printf("Error marshaling JSON: %v", err)
-		return
+		return nil, fmt.Errorf("marshaling JSON: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", "https://api.e-z.host/shortener", bytes.NewBuffer(jsonData))
 	if err != nil {
-		log.Printf("Error creating request: %v", err)
-		return
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -192,29 +103,20 @@ func shortenURL(apiKey, url string) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Error performing request: %v", err)
-		return
+		return nil, fmt.Errorf("performing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		responseBody, _ := ioutil.ReadAll(resp.Body)
-		log.Printf("Error: Received non-OK response: %d\nResponse: %s", resp.StatusCode, string(responseBody))
-		return
+		return nil, fmt.Errorf("received non-OK response: %d\nresponse: %s", resp.StatusCode, string(responseBody))
 	}
 
 	var jsonResponse Response
 	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
-		log.Println("Failed to parse JSON response.")
-		return
-	}
-
-	if jsonResponse.Success {
-		fmt.Println("Shortened URL:", jsonResponse.ShortenedURL)
-		copyToClipboard(jsonResponse.ShortenedURL)
-	} else {
-		log.Println("URL shortening failed:", jsonResponse.Message)
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
+	return &jsonResponse, nil
 }
 
 func promptForInput(prompt string) string {
@@ -238,40 +140,130 @@ func main() {
 		return
 	}
 
-	var filePath string
 	var urlToShorten string
 
+	resume := false
+	recursive := false
+	asJSON := false
+	concurrency := 4
+	manifestPath := ""
+	backendPath := ""
+	allowedMimePatterns := loadConfig().AllowedMimePatterns
+	for i, arg := range os.Args[1:] {
+		switch arg {
+		case "--resume":
+			resume = true
+		case "--recursive":
+			recursive = true
+		case "--json":
+			asJSON = true
+		case "--concurrency":
+			if i+2 < len(os.Args) {
+				if n, err := strconv.Atoi(os.Args[i+2]); err == nil {
+					concurrency = n
+				}
+			}
+		case "--manifest":
+			if i+2 < len(os.Args) && !strings.HasPrefix(os.Args[i+2], "-") {
+				manifestPath = os.Args[i+2]
+			}
+		case "--backend":
+			if i+2 < len(os.Args) {
+				backendPath = os.Args[i+2]
+			}
+		case "--allow-mime":
+			if i+2 < len(os.Args) {
+				allowedMimePatterns = parseMimePatterns(os.Args[i+2])
+			}
+		}
+	}
+
+	exitCode := 0
+
 	for i := 1; i < len(os.Args); i++ {
 		arg := os.Args[i]
 		switch arg {
 		case "--help", "-h":
 			displayHelp()
 			return
-		case "--api-key", "-a":
+		case "--resume", "--recursive", "--json":
+			// handled by the pre-scan above; consumed here so it isn't
+			// reported as an unknown option.
+		case "--concurrency":
 			if i+1 < len(os.Args) {
-				saveApiKey(os.Args[i+1])
 				i++
-			} else {
-				saveApiKey(promptForInput("Enter API Key: "))
 			}
-		case "--upload", "-u":
+		case "--manifest":
+			if i+1 < len(os.Args) && !strings.HasPrefix(os.Args[i+1], "-") {
+				i++
+			}
+		case "--backend":
 			if i+1 < len(os.Args) {
-				filePath = os.Args[i+1]
 				i++
-			} else {
-				filePath = promptForInput("Enter file path to upload: ")
 			}
-			apiKey := getApiKeyOrPrompt()
-			uploadFile(filePath, apiKey, false)
-		case "--upload-raw", "-ur":
+		case "--allow-mime":
+			// handled by the pre-scan above; consumed here so it isn't
+			// reported as an unknown option.
 			if i+1 < len(os.Args) {
-				filePath = os.Args[i+1]
+				i++
+			}
+		case "--api-key", "-a":
+			if i+1 < len(os.Args) {
+				saveApiKey(os.Args[i+1])
 				i++
 			} else {
-				filePath = promptForInput("Enter file path to upload: ")
+				saveApiKey(promptForInput("Enter API Key: "))
+			}
+		case "--upload", "-u", "--upload-raw", "-ur":
+			copyRawURL := arg == "--upload-raw" || arg == "-ur"
+
+			var paths []string
+			for i+1 < len(os.Args) && !strings.HasPrefix(os.Args[i+1], "-") {
+				paths = append(paths, os.Args[i+1])
+				i++
 			}
+			if len(paths) == 0 {
+				paths = []string{promptForInput("Enter file path to upload: ")}
+			}
+
+			resolved, err := resolveUploadPaths(paths, recursive)
+			if err != nil {
+				log.Printf("Error resolving upload paths: %v", err)
+				break
+			}
+
 			apiKey := getApiKeyOrPrompt()
-			uploadFile(filePath, apiKey, true)
+
+			if backendPath != "" {
+				backend, err := loadBackend(backendPath, apiKey)
+				if err != nil {
+					log.Printf("Error loading backend: %v", err)
+					exitCode = 1
+					break
+				}
+				for _, path := range resolved {
+					if err := uploadViaBackend(path, backend, copyRawURL, allowedMimePatterns); err != nil {
+						log.Printf("Error uploading %s: %v", path, err)
+						exitCode = 1
+					}
+				}
+				break
+			}
+
+			if len(resolved) == 1 {
+				if !uploadFile(resolved[0], apiKey, copyRawURL, resume, allowedMimePatterns) {
+					exitCode = 1
+				}
+				break
+			}
+
+			results := runBatchUpload(resolved, apiKey, concurrency, resume, allowedMimePatterns)
+			if err := writeManifest(results, manifestPath); err != nil {
+				log.Printf("Error writing manifest: %v", err)
+			}
+			if failed := summarizeBatch(results); failed > 0 {
+				exitCode = 1
+			}
 		case "--shorten", "-s":
 			if i+1 < len(os.Args) {
 				urlToShorten = os.Args[i+1]
@@ -280,9 +272,80 @@ func main() {
 				urlToShorten = promptForInput("Enter URL to shorten: ")
 			}
 			apiKey := getApiKeyOrPrompt()
+			if backendPath != "" {
+				backend, err := loadBackend(backendPath, apiKey)
+				if err != nil {
+					log.Printf("Error loading backend: %v", err)
+					exitCode = 1
+					break
+				}
+				resp, err := backend.Shorten(urlToShorten)
+				if err != nil {
+					log.Printf("Error shortening URL: %v", err)
+					exitCode = 1
+					break
+				}
+				fmt.Println("Shortened URL:", resp.ShortenedURL)
+				copyToClipboard(resp.ShortenedURL)
+				break
+			}
 			shortenURL(apiKey, urlToShorten)
+		case "--history":
+			entries, err := loadHistory()
+			if err != nil {
+				log.Printf("Error loading history: %v", err)
+				exitCode = 1
+				break
+			}
+			printHistory(entries, asJSON)
+		case "--delete":
+			var urlOrIndex string
+			if i+1 < len(os.Args) {
+				urlOrIndex = os.Args[i+1]
+				i++
+			} else {
+				urlOrIndex = promptForInput("Enter URL or history index to delete: ")
+			}
+
+			entries, err := loadHistory()
+			if err != nil {
+				log.Printf("Error loading history: %v", err)
+				exitCode = 1
+				break
+			}
+			deletionURL, err := findDeletionURL(entries, urlOrIndex)
+			if err != nil {
+				log.Printf("Error: %v", err)
+				exitCode = 1
+				break
+			}
+			if err := deleteUpload(deletionURL); err != nil {
+				log.Printf("Error deleting upload: %v", err)
+				exitCode = 1
+				break
+			}
+			fmt.Println("Upload deleted.")
+		case "--purge-older-than":
+			if i+1 >= len(os.Args) {
+				log.Println("Error: --purge-older-than requires a duration, e.g. 30d")
+				exitCode = 1
+				break
+			}
+			retention, err := parseRetention(os.Args[i+1])
+			i++
+			if err != nil {
+				log.Printf("Error: %v", err)
+				exitCode = 1
+				break
+			}
+			if err := purgeOlderThan(retention); err != nil {
+				log.Printf("Error purging history: %v", err)
+				exitCode = 1
+			}
 		default:
 			log.Println("Unknown option:", arg)
 		}
 	}
+
+	os.Exit(exitCode)
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultAllowedMimePatterns is used when no config file is present and no
+// --allow-mime override was given on the command line.
+var defaultAllowedMimePatterns = []string{
+	"image/*",
+	"video/*",
+	"audio/*",
+	"application/pdf",
+	"application/zip",
+	"application/json",
+	"text/plain",
+	"text/csv",
+}
+
+// textLikeExtensions maps extensions to a more specific MIME type for
+// files that http.DetectContentType can only sniff down to some flavor of
+// "text/plain" or "text/xml" -- it has no signature for JSON, CSV, or bare
+// SVG (one without a leading "<?xml" declaration), so without this they'd
+// sniff identically to any other text file.
+var textLikeExtensions = map[string]string{
+	".json": "application/json",
+	".csv":  "text/csv",
+	".txt":  "text/plain",
+	".svg":  "image/svg+xml",
+}
+
+// detectMimeType sniffs the true content type of filePath by inspecting its
+// first 512 bytes via http.DetectContentType, rather than trusting the file
+// extension, which can be spoofed or simply absent. For the text family,
+// where sniffing alone can't tell JSON from CSV from plain text, it
+// refines the result using the extension via textLikeExtensions.
+func detectMimeType(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && n == 0 {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(buf[:n])
+	parsed := contentType
+	if p, _, err := mime.ParseMediaType(contentType); err == nil {
+		parsed = p
+	}
+
+	if strings.HasPrefix(parsed, "text/") {
+		if specific, ok := textLikeExtensions[strings.ToLower(filepath.Ext(filePath))]; ok {
+			return specific, nil
+		}
+	}
+	return parsed, nil
+}
+
+// mimeAllowed reports whether mimeType matches one of patterns, where a
+// pattern may end in "/*" to match an entire type family (e.g. "image/*").
+func mimeAllowed(mimeType string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == mimeType {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") {
+			prefix := strings.TrimSuffix(pattern, "*")
+			if strings.HasPrefix(mimeType, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseMimePatterns splits a comma-separated --allow-mime argument into
+// individual patterns, trimming whitespace around each one.
+func parseMimePatterns(arg string) []string {
+	var patterns []string
+	for _, p := range strings.Split(arg, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// createFormFilePart behaves like multipart.Writer.CreateFormFile, but sets
+// contentType on the part instead of defaulting to application/octet-stream.
+func createFormFilePart(writer *multipart.Writer, fieldName, fileName, contentType string) (io.Writer, error) {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fieldName, fileName))
+	header.Set("Content-Type", contentType)
+	return writer.CreatePart(header)
+}
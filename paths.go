@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appConfigDir returns the directory e-z stores its config, history, and
+// state in: %APPDATA%\e-z on Windows, ~/Library/Application Support/e-z
+// on macOS, and ~/.config/e-z on Linux (via os.UserConfigDir).
+func appConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "e-z")
+}
+
+// apiKeyPath returns where the API key is stored. It honors a key saved at
+// the old flat ~/.config/.e-z_key location from before e-z switched to
+// appConfigDir, so upgrading doesn't orphan an existing key.
+func apiKeyPath() string {
+	legacy := filepath.Join(os.Getenv("HOME"), ".config", ".e-z_key")
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy
+	}
+	return filepath.Join(appConfigDir(), ".e-z_key")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// progressBar renders a terminal progress bar for a long-running
+// byte-oriented operation such as a file upload. It writes to stderr so
+// stdout stays reserved for machine-readable output like the batch
+// manifest.
+type progressBar struct {
+	total     int64
+	read      int64
+	quiet     bool
+	startTime time.Time
+	lastDraw  time.Time
+}
+
+// newProgressBar creates a bar for total bytes. A quiet bar tracks progress
+// without drawing anything, used when multiple bars would otherwise fight
+// over the same terminal line (e.g. the batch uploader's workers).
+func newProgressBar(total int64, quiet bool) *progressBar {
+	return &progressBar{total: total, quiet: quiet, startTime: time.Now()}
+}
+
+// add advances the bar by n bytes and redraws it, throttled to at most
+// once every 100ms so large files don't flood the terminal.
+func (p *progressBar) add(n int64) {
+	p.read += n
+	if p.quiet {
+		return
+	}
+	if p.read >= p.total || time.Since(p.lastDraw) > 100*time.Millisecond {
+		p.draw()
+		p.lastDraw = time.Now()
+	}
+}
+
+func (p *progressBar) draw() {
+	const barWidth = 30
+
+	ratio := 0.0
+	if p.total > 0 {
+		ratio = float64(p.read) / float64(p.total)
+		if ratio > 1 {
+			ratio = 1
+		}
+	}
+	filled := int(ratio * barWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+
+	elapsed := time.Since(p.startTime).Seconds()
+	var throughput float64
+	if elapsed > 0 {
+		throughput = float64(p.read) / elapsed
+	}
+
+	eta := "?"
+	if throughput > 0 && p.total > 0 {
+		remaining := float64(p.total-p.read) / throughput
+		eta = fmt.Sprintf("%.0fs", remaining)
+	}
+
+	fmt.Fprintf(os.Stderr, "\r[%s] %s/%s  %s/s  ETA %s", bar,
+		humanizeBytes(p.read), humanizeBytes(p.total), humanizeBytes(int64(throughput)), eta)
+	if p.read >= p.total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// progressReader wraps an io.Reader, reporting every read to a progressBar
+// so callers can stream data while still driving a progress display.
+type progressReader struct {
+	reader io.Reader
+	bar    *progressBar
+}
+
+func newProgressReader(r io.Reader, bar *progressBar) *progressReader {
+	return &progressReader{reader: r, bar: bar}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if n > 0 {
+		p.bar.add(int64(n))
+	}
+	return n, err
+}
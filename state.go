@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// uploadState is the on-disk sidecar written alongside a file being
+// uploaded with --resume, tracking which bytes have already been
+// acknowledged by the server so an interrupted upload can continue
+// from the last completed offset instead of starting over.
+type uploadState struct {
+	FilePath  string `json:"filePath"`
+	FileSize  int64  `json:"fileSize"`
+	ChunkSize int64  `json:"chunkSize"`
+	SessionID string `json:"sessionId"`
+	Offset    int64  `json:"offset"`
+}
+
+func stateFilePath(filePath string) string {
+	return filePath + ".e-z_state"
+}
+
+// loadUploadState reads the sidecar for filePath, if one exists.
+func loadUploadState(filePath string) (*uploadState, error) {
+	data, err := os.ReadFile(stateFilePath(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	var st uploadState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (st *uploadState) save() error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(stateFilePath(st.FilePath), data, 0644)
+}
+
+func (st *uploadState) remove() {
+	os.Remove(stateFilePath(st.FilePath))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// sxcuConfig is the subset of the ShareX custom-uploader format
+// (https://getsharex.com/docs/custom-uploader) that e-z understands: a
+// multipart file upload with optional extra form fields, and response
+// fields pulled out of the JSON body with "$json:path$" placeholders.
+type sxcuConfig struct {
+	RequestURL   string            `json:"RequestURL"`
+	Headers      map[string]string `json:"Headers"`
+	Body         string            `json:"Body"`
+	FileFormName string            `json:"FileFormName"`
+	Arguments    map[string]string `json:"Arguments"`
+	URL          string            `json:"URL"`
+	DeletionURL  string            `json:"DeletionURL"`
+}
+
+// loadSXCUConfig reads a ShareX .sxcu custom-uploader file.
+func loadSXCUConfig(path string) (*sxcuConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var cfg sxcuConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if cfg.FileFormName == "" {
+		cfg.FileFormName = "file"
+	}
+	return &cfg, nil
+}
+
+// sxcuBackend uploads through a host described by a ShareX custom-uploader
+// config, letting e-z target Imgur, self-hosted Zipline/Chibisafe, or any
+// other ShareX-compatible service.
+type sxcuBackend struct {
+	cfg *sxcuConfig
+}
+
+func newSXCUBackend(cfg *sxcuConfig) *sxcuBackend {
+	return &sxcuBackend{cfg: cfg}
+}
+
+func (b *sxcuBackend) Upload(r io.Reader, name, mimeType string) (*Response, error) {
+	if !strings.EqualFold(b.cfg.Body, "MultipartFormData") && b.cfg.Body != "" {
+		return nil, fmt.Errorf("unsupported Body type %q (only MultipartFormData is supported)", b.cfg.Body)
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		for key, value := range b.cfg.Arguments {
+			if err := writer.WriteField(key, value); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		part, err := createFormFilePart(writer, b.cfg.FileFormName, name, mimeType)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, r); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(writer.Close())
+	}()
+
+	req, err := http.NewRequest("POST", b.cfg.RequestURL, pr)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	for key, value := range b.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	return b.doRequest(req)
+}
+
+func (b *sxcuBackend) Shorten(targetURL string) (*Response, error) {
+	form := url.Values{}
+	for key, value := range b.cfg.Arguments {
+		form.Set(key, strings.ReplaceAll(value, "{input}", targetURL))
+	}
+
+	req, err := http.NewRequest("POST", b.cfg.RequestURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for key, value := range b.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	return b.doRequest(req)
+}
+
+func (b *sxcuBackend) doRequest(req *http.Request) (*Response, error) {
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("performing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("received non-OK response: %d\nresponse: %s", resp.StatusCode, string(body))
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing JSON response: %w", err)
+	}
+
+	return &Response{
+		Success:      true,
+		ImageURL:     resolvePlaceholder(b.cfg.URL, parsed),
+		RawURL:       resolvePlaceholder(b.cfg.URL, parsed),
+		ShortenedURL: resolvePlaceholder(b.cfg.URL, parsed),
+		DeletionURL:  resolvePlaceholder(b.cfg.DeletionURL, parsed),
+	}, nil
+}
+
+// resolvePlaceholder expands a ShareX "$json:a.b.c$" placeholder against a
+// decoded JSON response, walking nested objects by dotted path. Templates
+// without a $json:...$ placeholder are returned unchanged.
+func resolvePlaceholder(template string, parsed interface{}) string {
+	if !strings.Contains(template, "$json:") {
+		return template
+	}
+
+	start := strings.Index(template, "$json:")
+	rest := template[start+len("$json:"):]
+	end := strings.Index(rest, "$")
+	if end == -1 {
+		return template
+	}
+	path := rest[:end]
+
+	var current interface{} = parsed
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current, ok = obj[key]
+		if !ok {
+			return ""
+		}
+	}
+
+	if s, ok := current.(string); ok {
+		return template[:start] + s + template[start+len("$json:")+end+1:]
+	}
+	return ""
+}
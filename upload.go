@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const defaultChunkSize = 5 * 1024 * 1024 // 5MB
+
+// uploadFile streams filePath to the e-z.host API, rendering a progress
+// bar as it goes, then copies the resulting URL to the clipboard. It
+// reports whether the upload succeeded so callers can set a non-zero
+// exit code on failure, same as the batch path. For batch uploads where
+// per-file clipboard copying and printing isn't wanted, use
+// uploadFileData directly.
+func uploadFile(filePath, apiKey string, copyRawURL, resume bool, allowedMimePatterns []string) bool {
+	jsonResponse, err := uploadFileData(filePath, apiKey, resume, allowedMimePatterns, false)
+	if err != nil {
+		log.Printf("Error uploading file: %v", err)
+		return false
+	}
+
+	if !jsonResponse.Success {
+		log.Println("Upload failed:", jsonResponse.Message)
+		return false
+	}
+
+	urlToCopy := jsonResponse.RawURL
+	if !copyRawURL {
+		urlToCopy = jsonResponse.ImageURL
+	}
+
+	copyToClipboard(urlToCopy)
+	fmt.Println("File uploaded and URL copied to clipboard.")
+
+	if sum, err := sha256File(filePath); err == nil {
+		entry := historyEntry{
+			Timestamp:   time.Now(),
+			LocalPath:   filePath,
+			SHA256:      sum,
+			ImageURL:    jsonResponse.ImageURL,
+			RawURL:      jsonResponse.RawURL,
+			DeletionURL: jsonResponse.DeletionURL,
+		}
+		if err := recordHistory(entry); err != nil {
+			log.Printf("Warning: failed to record upload history: %v", err)
+		}
+	}
+
+	return true
+}
+
+// uploadFileData runs the upload pipeline for filePath (MIME validation,
+// streaming or resumable transfer) and returns the raw API response
+// without touching the clipboard, so callers like the batch uploader can
+// handle the result themselves. quiet suppresses the per-file progress
+// bar; the batch uploader sets it since concurrent workers can't share
+// one `\r`-drawn terminal line.
+func uploadFileData(filePath, apiKey string, resume bool, allowedMimePatterns []string, quiet bool) (*Response, error) {
+	fileInfo, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("file does not exist")
+	}
+
+	if fileInfo.Size() > maxFileSize {
+		return nil, fmt.Errorf("file size exceeds 100MB")
+	}
+
+	mimeType, err := detectMimeType(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("detecting MIME type: %w", err)
+	}
+	if !mimeAllowed(mimeType, allowedMimePatterns) {
+		return nil, fmt.Errorf("MIME type %q is not allowed", mimeType)
+	}
+
+	if resume {
+		return uploadFileResumable(filePath, apiKey, fileInfo.Size(), mimeType, quiet)
+	}
+	return uploadFileStreaming(filePath, apiKey, fileInfo.Size(), mimeType, quiet)
+}
+
+// uploadFileStreaming pipes the file straight into the multipart request
+// body via io.Pipe so the whole file never has to be buffered in memory.
+func uploadFileStreaming(filePath, apiKey string, size int64, mimeType string, quiet bool) (*Response, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	bar := newProgressBar(size, quiet)
+
+	go func() {
+		part, err := createFormFilePart(writer, "file", filepath.Base(filePath), mimeType)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		if _, err := io.Copy(part, newProgressReader(file, bar)); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(writer.Close())
+	}()
+
+	req, err := http.NewRequest("POST", "https://api.e-z.host/files", pr)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("key", apiKey)
+
+	return doUploadRequest(req)
+}
+
+// uploadFileResumable uploads filePath in fixed-size chunks, persisting
+// progress to a local sidecar after each acknowledged chunk so the upload
+// can continue from the last completed offset if the process is interrupted.
+func uploadFileResumable(filePath, apiKey string, size int64, mimeType string, quiet bool) (*Response, error) {
+	state, err := loadUploadState(filePath)
+	if err != nil || state.FileSize != size {
+		state = &uploadState{FilePath: filePath, FileSize: size, ChunkSize: defaultChunkSize}
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	bar := newProgressBar(size, quiet)
+	bar.add(state.Offset)
+
+	for state.Offset < state.FileSize {
+		chunkLen := state.ChunkSize
+		if remaining := state.FileSize - state.Offset; remaining < chunkLen {
+			chunkLen = remaining
+		}
+
+		if _, err := file.Seek(state.Offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seeking to offset %d: %w", state.Offset, err)
+		}
+
+		result, err := uploadChunk(apiKey, state, io.LimitReader(file, chunkLen), mimeType)
+		if err != nil {
+			return nil, fmt.Errorf("uploading chunk at offset %d: %w", state.Offset, err)
+		}
+
+		state.SessionID = result.sessionID
+		state.Offset += chunkLen
+		bar.add(chunkLen)
+
+		if state.Offset >= state.FileSize {
+			state.remove()
+			return result.final, nil
+		}
+		if err := state.save(); err != nil {
+			log.Printf("Warning: failed to save resume state: %v", err)
+		}
+	}
+
+	return nil, fmt.Errorf("upload loop exited without completing")
+}
+
+type chunkUploadResult struct {
+	sessionID string
+	final     *Response
+}
+
+// uploadChunk sends one chunk of a resumable upload, starting a new
+// session on the server if state.SessionID hasn't been assigned yet.
+func uploadChunk(apiKey string, state *uploadState, r io.Reader, mimeType string) (*chunkUploadResult, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		part, err := createFormFilePart(writer, "file", filepath.Base(state.FilePath), mimeType)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, r); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(writer.Close())
+	}()
+
+	url := "https://api.e-z.host/files/chunked"
+	if state.SessionID != "" {
+		url = fmt.Sprintf("https://api.e-z.host/files/chunked/%s", state.SessionID)
+	}
+
+	req, err := http.NewRequest("POST", url, pr)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("key", apiKey)
+	req.Header.Set("X-Chunk-Offset", fmt.Sprintf("%d", state.Offset))
+	req.Header.Set("X-Chunk-Total-Size", fmt.Sprintf("%d", state.FileSize))
+
+	resp, err := doUploadRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkUploadResult{sessionID: resp.SessionID, final: resp}, nil
+}
+
+func doUploadRequest(req *http.Request) (*Response, error) {
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("performing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("received non-OK response: %d\nresponse: %s", resp.StatusCode, string(responseBody))
+	}
+
+	var jsonResponse Response
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return &jsonResponse, nil
+}